@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	log "github.com/sirupsen/logrus"
@@ -18,13 +19,19 @@ type cliOpts struct {
 }
 
 type List struct {
-	NoColor bool `long:"no-color" description:"disable colored output"`
+	NoColor       bool    `long:"no-color" description:"disable colored output"`
+	MinConfidence float64 `long:"min-confidence" description:"minimum classifier confidence, 0-1, required to accept a license match" default:"0.8"`
+	LicensesFile  string  `long:"licenses-file" description:"YAML file with a licenses: section registering custom license URLs/templates" default:""`
 }
 
 type Check struct {
-	File      string `short:"f" long:"file" description:"input file" default:".wwhrd.yml"`
-	ReportOut string `short:"r" long:"report-out" description:"report of all licenses found" default:""`
-	NoColor   bool   `long:"no-color" description:"disable colored output"`
+	File          string  `short:"f" long:"file" description:"input file" default:".wwhrd.yml"`
+	ReportOut     string  `short:"r" long:"report-out" description:"report of all licenses found" default:""`
+	NoColor       bool    `long:"no-color" description:"disable colored output"`
+	SbomFormat    string  `long:"sbom-format" description:"emit an SBOM alongside the license check (spdx-tv, spdx-json, cyclonedx-json)"`
+	SbomOut       string  `long:"sbom-out" description:"file to write the SBOM to, defaults to stdout" default:""`
+	MinConfidence float64 `long:"min-confidence" description:"minimum classifier confidence, 0-1, required to accept a license match" default:"0.8"`
+	LicensesFile  string  `long:"licenses-file" description:"YAML file with a licenses: section registering custom license URLs/templates" default:""`
 }
 
 const VersionHelp flags.ErrorType = 1961
@@ -69,18 +76,31 @@ func (l *List) Execute(args []string) error {
 		return err
 	}
 
-	pkgs, err := WalkImports(root)
+	pkgs, _, err := WalkImports(root)
 	if err != nil {
 		return err
 	}
-	lics := GetLicenses(root, pkgs)
+
+	var custom []CustomLicense
+	if l.LicensesFile != "" {
+		custom, err = LoadLicensesFile(l.LicensesFile)
+		if err != nil {
+			return fmt.Errorf("Can't read licenses file: %s", err)
+		}
+	}
+
+	lics := GetLicenses(pkgs, l.MinConfidence, custom)
 
 	for k, v := range lics {
 		if v.Recognized() {
-			log.WithFields(log.Fields{
+			fields := log.Fields{
 				"package": k,
 				"license": v.Type,
-			}).Info("Found License")
+			}
+			if v.Attribution != "" {
+				fields["attribution"] = v.Attribution
+			}
+			log.WithFields(fields).Info("Found License")
 		} else {
 			log.WithFields(log.Fields{
 				"package": k,
@@ -120,32 +140,34 @@ func (c *Check) Execute(args []string) error {
 		return err
 	}
 
-	pkgs, err := WalkImports(root)
+	pkgs, rootModule, err := WalkImports(root)
 	if err != nil {
 		return err
 	}
-	lics := GetLicenses(root, pkgs)
 
-	// Make a map out of the blacklist
-	blacklist := make(map[string]bool)
-	for _, v := range t.Blacklist {
-		blacklist[v] = true
+	var filteredPkgs []Import
+	for _, imp := range pkgs {
+		if t.Excluded(imp.Path) {
+			continue
+		}
+		filteredPkgs = append(filteredPkgs, imp)
 	}
+	pkgs = filteredPkgs
 
-	// Make a map out of the whitelist
-	whitelist := make(map[string]bool)
-	for _, v := range t.Whitelist {
-		whitelist[v] = true
+	custom := t.Licenses
+	if c.LicensesFile != "" {
+		fileCustom, err := LoadLicensesFile(c.LicensesFile)
+		if err != nil {
+			return fmt.Errorf("Can't read licenses file: %s", err)
+		}
+		custom = append(custom, fileCustom...)
 	}
 
-	// Make a map out of the exceptions list
-	exceptions := make(map[string]bool)
-	exceptionsWildcard := make(map[string]bool)
-	for _, v := range t.Exceptions {
-		if strings.HasSuffix(v, "/...") {
-			exceptionsWildcard[strings.TrimRight(v, "/...")] = true
-		} else {
-			exceptions[v] = true
+	lics := GetLicenses(pkgs, c.MinConfidence, custom)
+
+	if c.SbomFormat != "" {
+		if err := WriteSBOM(c.SbomFormat, c.SbomOut, lics, rootModule, time.Now().UTC()); err != nil {
+			return fmt.Errorf("Can't write SBOM: %s", err)
 		}
 	}
 
@@ -174,13 +196,41 @@ PackageList:
 			}
 		}
 
-		contextLogger := log.WithFields(log.Fields{
+		logFields := log.Fields{
 			"package": pkg,
 			"license": lic.Type,
-		})
+		}
+		if lic.Attribution != "" {
+			logFields["attribution"] = lic.Attribution
+		}
+		contextLogger := log.WithFields(logFields)
+
+		// A pinned override asserts a known-good license for this package,
+		// but only while its resolved version still matches the pin; a
+		// version drift is treated as a potential unreviewed license change.
+		if ov, ok := t.OverrideFor(pkg); ok {
+			if !versionMatches(ov.Version, lic.Version) {
+				contextLogger.WithFields(log.Fields{
+					"pinned_version":   ov.Version,
+					"resolved_version": lic.Version,
+				}).Error("Pinned package version drifted")
+				err = fmt.Errorf("Pinned package version drifted")
+				continue PackageList
+			}
+
+			contextLogger.WithField("override_license", ov.License).Info("Found pinned override license")
+			continue PackageList
+		}
+
+		// Select the most specific root-scoped policy for this package.
+		policy := t.PolicyFor(pkg)
+		blacklist := toSet(policy.Blacklist)
+		whitelist := toSet(policy.Whitelist)
+		exceptions, exceptionsWildcard := splitExceptions(policy.Exceptions)
 
-		// License is whitelisted and not specified in blacklist
-		if whitelist[lic.Type] && !blacklist[lic.Type] {
+		// License expression (e.g. "MIT" or "MIT OR Apache-2.0") is
+		// whitelisted per the dual-license rules in Expr.Approved.
+		if expr, err := lic.Expression(); err == nil && expr.Approved(whitelist, blacklist) {
 			contextLogger.Info("Found Approved license")
 			continue PackageList
 		}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Target represents the parsed .wwhrd.yml policy file. The top-level
+// Blacklist/Whitelist/Exceptions fields are the default policy, applied to
+// any package that no Policies entry claims.
+type Target struct {
+	Blacklist  []string        `yaml:"blacklist"`
+	Whitelist  []string        `yaml:"whitelist"`
+	Exceptions []string        `yaml:"exceptions"`
+	Policies   []Policy        `yaml:"policies"`
+	Licenses   []CustomLicense `yaml:"licenses"`
+	Overrides  []Override      `yaml:"overrides"`
+	Excludes   []string        `yaml:"excludes"`
+}
+
+// Override pins a package - by exact import path or filepath.Match-style
+// glob - to an exact (or glob) version and asserts its license. A package
+// matching Name whose resolved version doesn't match Version is treated as
+// a version drift and fails the check, so a minor bump that silently
+// changes the license can't slip through unreviewed.
+type Override struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	License string `yaml:"license"`
+}
+
+// matchName reports whether pkg matches name, which may be an exact import
+// path or a filepath.Match-style glob (e.g. "github.com/foo/*").
+func matchName(name, pkg string) bool {
+	if !strings.ContainsAny(name, "*?[") {
+		return name == pkg
+	}
+	ok, err := filepath.Match(name, pkg)
+	return err == nil && ok
+}
+
+// versionMatches reports whether version satisfies pattern, which may be an
+// exact version or a filepath.Match-style glob (e.g. "v1.2.*"). An empty
+// pattern matches any version.
+func versionMatches(pattern, version string) bool {
+	if pattern == "" {
+		return true
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		return pattern == version
+	}
+	ok, err := filepath.Match(pattern, version)
+	return err == nil && ok
+}
+
+// Excluded reports whether pkg is removed from consideration entirely by an
+// excludes: entry.
+func (t *Target) Excluded(pkg string) bool {
+	for _, ex := range t.Excludes {
+		if matchName(ex, pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// OverrideFor returns the Override pinning pkg, if any.
+func (t *Target) OverrideFor(pkg string) (Override, bool) {
+	for _, o := range t.Overrides {
+		if matchName(o.Name, pkg) {
+			return o, true
+		}
+	}
+	return Override{}, false
+}
+
+// CustomLicense registers an additional license identifier for the
+// classifier to recognize, either by one or more canonical URLs that force
+// the classification when found verbatim in a package's license file, or by
+// paths to local template files to add to the classifier corpus.
+type CustomLicense struct {
+	SPDXID    string   `yaml:"spdx_id"`
+	URLs      []string `yaml:"urls"`
+	Templates []string `yaml:"templates"`
+}
+
+// licensesFile is the shape of a file passed via --licenses-file: just the
+// licenses: section on its own, so it can be shared outside a full
+// .wwhrd.yml (e.g. across multiple repos or passed to `list`).
+type licensesFile struct {
+	Licenses []CustomLicense `yaml:"licenses"`
+}
+
+// LoadLicensesFile reads a standalone licenses: document and returns its
+// CustomLicense entries.
+func LoadLicensesFile(path string) ([]CustomLicense, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f licensesFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+
+	return f.Licenses, nil
+}
+
+// Policy is a root-scoped license policy: it applies to any package whose
+// import path is prefixed by Root, overriding the default policy for that
+// subtree.
+type Policy struct {
+	Root       string   `yaml:"root"`
+	Blacklist  []string `yaml:"blacklist"`
+	Whitelist  []string `yaml:"whitelist"`
+	Exceptions []string `yaml:"exceptions"`
+}
+
+// ReadConfig loads and parses a wwhrd policy file from disk.
+func ReadConfig(file string) (*Target, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var t Target
+	if err := yaml.Unmarshal(b, &t); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// PolicyFor returns the most specific Policy applying to pkg: the Policies
+// entry whose Root is the longest matching prefix, or the Target's top-level
+// fields as the default policy when no Policies entry matches.
+func (t *Target) PolicyFor(pkg string) Policy {
+	def := Policy{Blacklist: t.Blacklist, Whitelist: t.Whitelist, Exceptions: t.Exceptions}
+
+	best := def
+	bestLen := -1
+
+	for _, p := range t.Policies {
+		root := strings.TrimSuffix(strings.TrimSuffix(p.Root, "/..."), "/")
+		if root != "" && pkg != root && !strings.HasPrefix(pkg, root+"/") {
+			continue
+		}
+		if len(root) > bestLen {
+			best = p
+			bestLen = len(root)
+		}
+	}
+
+	return best
+}
+
+// toSet builds a lookup set out of a policy's string list.
+func toSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, v := range list {
+		set[v] = true
+	}
+	return set
+}
+
+// splitExceptions separates a policy's Exceptions list into exact package
+// matches and "/..."-suffixed prefix matches.
+func splitExceptions(list []string) (exact map[string]bool, wildcard map[string]bool) {
+	exact = make(map[string]bool)
+	wildcard = make(map[string]bool)
+	for _, v := range list {
+		if strings.HasSuffix(v, "/...") {
+			wildcard[strings.TrimSuffix(v, "/...")] = true
+		} else {
+			exact[v] = true
+		}
+	}
+	return exact, wildcard
+}
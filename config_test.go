@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+func TestPolicyForMatchesOnPathSegmentBoundaries(t *testing.T) {
+	target := &Target{
+		Blacklist: []string{"GPL-3.0"},
+		Policies: []Policy{
+			{Root: "github.com/foo", Whitelist: []string{"MIT"}},
+		},
+	}
+
+	if got := target.PolicyFor("github.com/foobar/baz"); len(got.Whitelist) != 0 {
+		t.Errorf("PolicyFor(github.com/foobar/baz) = %+v, want the default policy, not github.com/foo's", got)
+	}
+
+	if got := target.PolicyFor("github.com/foo/baz"); len(got.Whitelist) != 1 || got.Whitelist[0] != "MIT" {
+		t.Errorf("PolicyFor(github.com/foo/baz) = %+v, want github.com/foo's policy", got)
+	}
+
+	if got := target.PolicyFor("github.com/foo"); len(got.Whitelist) != 1 || got.Whitelist[0] != "MIT" {
+		t.Errorf("PolicyFor(github.com/foo) = %+v, want an exact root match to also use github.com/foo's policy", got)
+	}
+}
+
+func TestPolicyForPrefersLongestMatchingRoot(t *testing.T) {
+	target := &Target{
+		Policies: []Policy{
+			{Root: "github.com/foo", Whitelist: []string{"MIT"}},
+			{Root: "github.com/foo/bar", Whitelist: []string{"Apache-2.0"}},
+		},
+	}
+
+	got := target.PolicyFor("github.com/foo/bar/baz")
+	if len(got.Whitelist) != 1 || got.Whitelist[0] != "Apache-2.0" {
+		t.Errorf("PolicyFor(github.com/foo/bar/baz) = %+v, want the more specific github.com/foo/bar policy", got)
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Unknown is the license Type reported when no candidate met the confidence
+// threshold.
+const Unknown = "Unknown"
+
+// DefaultMinConfidence is the similarity score, in the range [0,1], a
+// candidate must meet or exceed to be accepted as a match.
+const DefaultMinConfidence = 0.8
+
+// LicenseCandidate is a single SPDX identifier the classifier considered a
+// plausible match for a license file, along with its confidence score and
+// the name of the template it was scored against.
+type LicenseCandidate struct {
+	SPDXID     string
+	Confidence float64
+	Template   string
+}
+
+// License describes the license(s) detected for a single package. Type
+// holds the SPDX expression built from Candidates (e.g. "MIT" or
+// "MIT OR Apache-2.0" for a dual-licensed package); Candidates holds every
+// template that scored at or above the configured confidence threshold,
+// sorted highest confidence first. Attribution names the custom URL or
+// template that drove the classification, when one did; it is empty for
+// matches against the built-in classifier. Version is the resolved module
+// version providing the package, as reported by WalkImports.
+type License struct {
+	Type        string
+	Text        string
+	Candidates  []LicenseCandidate
+	Attribution string
+	Version     string
+}
+
+// Recognized reports whether at least one candidate was matched.
+func (l License) Recognized() bool {
+	return l.Type != "" && l.Type != Unknown
+}
+
+// Expression parses Type as an SPDX license expression.
+func (l License) Expression() (*Expr, error) {
+	return ParseExpr(l.Type)
+}
+
+var licenseFileNames = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING", "license"}
+
+// licenseTemplates maps an SPDX identifier to the canonical template text a
+// license file's contents are scored against.
+var licenseTemplates = map[string]string{
+	"MIT": "Permission is hereby granted, free of charge, to any person obtaining a copy " +
+		"of this software and associated documentation files (the \"Software\"), to deal " +
+		"in the Software without restriction, including without limitation the rights " +
+		"to use, copy, modify, merge, publish, distribute, sublicense, and/or sell " +
+		"copies of the Software, and to permit persons to whom the Software is " +
+		"furnished to do so, subject to the following conditions.",
+	"Apache-2.0": "Licensed under the Apache License, Version 2.0 (the \"License\"); " +
+		"you may not use this file except in compliance with the License. " +
+		"You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0 " +
+		"Unless required by applicable law or agreed to in writing, software " +
+		"distributed under the License is distributed on an \"AS IS\" BASIS.",
+	"BSD-3-Clause": "Redistribution and use in source and binary forms, with or without " +
+		"modification, are permitted provided that the following conditions are met: " +
+		"Redistributions of source code must retain the above copyright notice, this " +
+		"list of conditions and the following disclaimer. Neither the name of the " +
+		"copyright holder nor the names of its contributors may be used to endorse or " +
+		"promote products derived from this software without specific prior written permission.",
+	"BSD-2-Clause": "Redistribution and use in source and binary forms, with or without " +
+		"modification, are permitted provided that the following conditions are met: " +
+		"Redistributions of source code must retain the above copyright notice, this " +
+		"list of conditions and the following disclaimer.",
+	"GPL-3.0": "This program is free software: you can redistribute it and/or modify " +
+		"it under the terms of the GNU General Public License as published by " +
+		"the Free Software Foundation, either version 3 of the License, or " +
+		"(at your option) any later version.",
+	"LGPL-3.0": "This library is free software: you can redistribute it and/or modify " +
+		"it under the terms of the GNU Lesser General Public License as published by " +
+		"the Free Software Foundation, either version 3 of the License, or " +
+		"(at your option) any later version.",
+	"MPL-2.0": "This Source Code Form is subject to the terms of the Mozilla Public " +
+		"License, v. 2.0. If a copy of the MPL was not distributed with this " +
+		"file, You can obtain one at http://mozilla.org/MPL/2.0/.",
+}
+
+// copyrightLine matches a leading copyright notice line so it doesn't skew
+// similarity scoring with names and years that don't appear in the
+// templates.
+var copyrightLine = regexp.MustCompile(`(?i)\s*Copyright\s*(?:\x{00A9}|\(c\))?\s*(?:\d{4}|\[year\]).*`)
+
+// declaredExpression matches an explicit SPDX-License-Identifier declaration
+// line, the only signal we trust for multi-operand ("X OR Y") expressions.
+// Without it, two templates both scoring above the confidence threshold is
+// far more likely to mean "these templates overlap" than "this file is
+// actually dual-licensed".
+var declaredExpression = regexp.MustCompile(`(?i)SPDX-License-Identifier:\s*([^\r\n]+)`)
+
+// punctuation matches anything that isn't a word character or whitespace,
+// so differences in quoting/punctuation between a real license file and our
+// templates don't fragment otherwise-identical words into distinct tokens.
+var punctuation = regexp.MustCompile(`[^\w\s]`)
+
+// urlScanFileNames are the files searched for a custom license's canonical
+// URL fingerprint, in addition to the files searched for license text.
+var urlScanFileNames = append(append([]string{}, licenseFileNames...), "NOTICE", "NOTICE.md", "README", "README.md")
+
+// customLicenseIndex indexes a user's CustomLicense entries for classifying
+// at runtime: canonical URLs that force a classification, and extra
+// template texts to score alongside the built-in corpus.
+type customLicenseIndex struct {
+	urls      map[string]string   // canonical URL -> SPDX ID
+	templates map[string][]string // SPDX ID -> template texts loaded from disk
+}
+
+func buildCustomLicenseIndex(custom []CustomLicense) customLicenseIndex {
+	idx := customLicenseIndex{urls: make(map[string]string), templates: make(map[string][]string)}
+
+	for _, c := range custom {
+		for _, url := range c.URLs {
+			idx.urls[url] = c.SPDXID
+		}
+		for _, path := range c.Templates {
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				log.WithFields(log.Fields{"template": path}).Warn("could not read custom license template")
+				continue
+			}
+			idx.templates[c.SPDXID] = append(idx.templates[c.SPDXID], string(b))
+		}
+	}
+
+	return idx
+}
+
+// GetLicenses classifies the license shipped in each import's module
+// directory (as resolved by WalkImports), keeping only candidates that meet
+// minConfidence. custom registers additional license identifiers on top of
+// the built-in classifier corpus.
+func GetLicenses(pkgs []Import, minConfidence float64, custom []CustomLicense) map[string]License {
+	idx := buildCustomLicenseIndex(custom)
+
+	out := make(map[string]License)
+
+	for _, pkg := range pkgs {
+		if pkg.Dir == "" {
+			log.WithFields(log.Fields{"package": pkg.Path}).Debug("could not resolve module directory")
+			out[pkg.Path] = License{Type: Unknown, Version: pkg.Version}
+			continue
+		}
+
+		lic := findLicense(pkg.Path, pkg.Dir, minConfidence, idx)
+		lic.Version = pkg.Version
+		out[pkg.Path] = lic
+	}
+
+	return out
+}
+
+// findLicense looks for a recognized license file in dir and classifies it.
+// A verbatim match against a custom license's canonical URL takes priority
+// over the similarity-based classifier.
+func findLicense(pkg, dir string, minConfidence float64, idx customLicenseIndex) License {
+	if lic, ok := findURLFingerprint(dir, idx); ok {
+		return lic
+	}
+
+	for _, name := range licenseFileNames {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		text := string(b)
+		typ, candidates, attribution := classify(text, minConfidence, idx.templates)
+		if typ == Unknown {
+			log.WithFields(log.Fields{"package": pkg, "file": name}).
+				Warn("license file found but no candidate met the confidence threshold")
+		}
+
+		return License{Type: typ, Text: text, Candidates: candidates, Attribution: attribution}
+	}
+
+	return License{Type: Unknown}
+}
+
+// findURLFingerprint scans dir's license/notice/readme files for a custom
+// license's canonical URL, returning the forced classification on a match.
+func findURLFingerprint(dir string, idx customLicenseIndex) (License, bool) {
+	if len(idx.urls) == 0 {
+		return License{}, false
+	}
+
+	for _, name := range urlScanFileNames {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		text := string(b)
+		for url, spdx := range idx.urls {
+			if strings.Contains(text, url) {
+				return License{Type: spdx, Text: text, Attribution: url}, true
+			}
+		}
+	}
+
+	return License{}, false
+}
+
+// classify scores text against the built-in and extra license templates and
+// returns the SPDX expression for the file, the candidates that met
+// minConfidence (sorted highest confidence first), and the Attribution to
+// record when the winning candidate came from an extra (custom) template.
+func classify(text string, minConfidence float64, extraTemplates map[string][]string) (string, []LicenseCandidate, string) {
+	tokens := tokenize(normalize(text))
+
+	var candidates []LicenseCandidate
+	templateSize := make(map[string]int)
+	for spdx, template := range licenseTemplates {
+		templateTokens := tokenize(normalize(template))
+		score := similarity(tokens, templateTokens)
+		if score >= minConfidence {
+			candidates = append(candidates, LicenseCandidate{SPDXID: spdx, Confidence: score, Template: spdx})
+			templateSize[spdx] = len(templateTokens)
+		}
+	}
+	for spdx, templates := range extraTemplates {
+		// A CustomLicense may register multiple templates for the same SPDX
+		// ID; score every one and keep whichever matched best.
+		best := -1.0
+		bestSize := 0
+		for _, template := range templates {
+			templateTokens := tokenize(normalize(template))
+			score := similarity(tokens, templateTokens)
+			if score > best {
+				best = score
+				bestSize = len(templateTokens)
+			}
+		}
+		if best >= minConfidence {
+			candidates = append(candidates, LicenseCandidate{SPDXID: spdx, Confidence: best, Template: "custom:" + spdx})
+			templateSize["custom:"+spdx] = bestSize
+		}
+	}
+
+	// A smaller, less specific template (e.g. BSD-2-Clause's wording, which
+	// is a subset of BSD-3-Clause's) almost always scores at least as high
+	// as a larger template it's nested inside, even when the file is really
+	// the more specific license: the real BSD-3-Clause text just has to omit
+	// or reword one phrase (e.g. swap "the copyright holder" for an author's
+	// name) to drop a couple points below the exact subset match. Ranking on
+	// confidence alone would then systematically prefer the less specific
+	// license. So once a template clears minConfidence, prefer the larger
+	// (more specific) template over a marginally higher score, and fall back
+	// to SPDXID so the result is fully deterministic.
+	sort.Slice(candidates, func(i, j int) bool {
+		if templateSize[candidates[i].Template] != templateSize[candidates[j].Template] {
+			return templateSize[candidates[i].Template] > templateSize[candidates[j].Template]
+		}
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].SPDXID < candidates[j].SPDXID
+	})
+
+	if len(candidates) == 0 {
+		// The similarity scan found nothing, but an explicit declaration is a
+		// stronger signal than any template match: trust it rather than
+		// reporting a license-bearing package as Unknown.
+		if declared, ok := declaredExpr(text); ok {
+			return declared, nil, ""
+		}
+		return Unknown, nil, ""
+	}
+
+	attribution := ""
+	if strings.HasPrefix(candidates[0].Template, "custom:") {
+		attribution = candidates[0].Template
+	}
+
+	return buildExpression(text, candidates), candidates, attribution
+}
+
+// buildExpression returns the SPDX expression for a classified license file.
+// Multiple templates scoring above the confidence threshold only means the
+// templates overlap, not that the file is dual-licensed, so a multi-operand
+// expression is only built when the file itself declares one via an
+// "SPDX-License-Identifier:" line; that declared expression is used as-is,
+// falling back to the single highest-confidence candidate otherwise.
+func buildExpression(text string, candidates []LicenseCandidate) string {
+	if declared, ok := declaredExpr(text); ok {
+		return declared
+	}
+
+	return candidates[0].SPDXID
+}
+
+// declaredExpr extracts a well-formed SPDX expression from an explicit
+// "SPDX-License-Identifier:" line in text, reporting ok=false if no such
+// line is present or it doesn't parse as a valid expression.
+func declaredExpr(text string) (string, bool) {
+	m := declaredExpression.FindStringSubmatch(text)
+	if m == nil {
+		return "", false
+	}
+
+	declared := strings.TrimSpace(m[1])
+	if _, err := ParseExpr(declared); err != nil {
+		return "", false
+	}
+
+	return declared, true
+}
+
+// normalize lowercases text, strips the copyright notice line and
+// punctuation, and collapses whitespace so similarity scoring isn't skewed
+// by formatting differences.
+func normalize(text string) string {
+	text = copyrightLine.ReplaceAllString(text, "")
+	text = strings.ToLower(text)
+	text = punctuation.ReplaceAllString(text, " ")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// tokenize splits normalized text into a word multiset.
+func tokenize(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, w := range strings.Fields(text) {
+		counts[w]++
+	}
+	return counts
+}
+
+// similarity computes how much of template is contained within text, as a
+// score in [0,1]: the fraction of template's words (by count) that also
+// appear in text, |text∩template| / |template|. This is deliberately a
+// containment metric rather than a symmetric one (e.g. Jaccard normalized
+// by max(|A|,|B|)): our templates are short representative excerpts, not
+// full license texts, so a real LICENSE file that fully contains a
+// template's wording plus its own boilerplate (copyright line, permission
+// notice, warranty disclaimer, etc.) should still score close to 1, not be
+// penalized for carrying more words than the excerpt.
+func similarity(text, template map[string]int) float64 {
+	inter, totalTemplate := 0, 0
+
+	for w, ct := range template {
+		totalTemplate += ct
+		if ctext, ok := text[w]; ok {
+			if ctext < ct {
+				inter += ctext
+			} else {
+				inter += ct
+			}
+		}
+	}
+
+	if totalTemplate == 0 {
+		return 0
+	}
+
+	return float64(inter) / float64(totalTemplate)
+}
@@ -0,0 +1,172 @@
+package main
+
+import "testing"
+
+const canonicalMIT = `MIT License
+
+Copyright (c) 2024 Jane Doe
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+`
+
+const canonicalApache2Header = `Copyright 2024 Jane Doe
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+`
+
+const canonicalBSD3 = `Copyright (c) 2024, Jane Doe
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products derived from
+   this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES ARE DISCLAIMED.
+`
+
+func TestClassifyRecognizesCanonicalLicensesAtDefaultConfidence(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"MIT", canonicalMIT, "MIT"},
+		{"Apache-2.0", canonicalApache2Header, "Apache-2.0"},
+		{"BSD-3-Clause", canonicalBSD3, "BSD-3-Clause"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			typ, candidates, _ := classify(c.text, DefaultMinConfidence, nil)
+			if typ != c.want {
+				t.Fatalf("classify(%s) = %q, candidates=%v, want %q", c.name, typ, candidates, c.want)
+			}
+		})
+	}
+}
+
+func TestClassifyOnlyBuildsExpressionFromDeclaredIdentifier(t *testing.T) {
+	// BSD-3-Clause textually contains BSD-2-Clause's wording, so both
+	// templates clear the confidence threshold; without a declared
+	// SPDX-License-Identifier line that must not be read as dual-licensing.
+	typ, _, _ := classify(canonicalBSD3, DefaultMinConfidence, nil)
+	if typ != "BSD-3-Clause" {
+		t.Fatalf("classify(BSD-3-Clause without declaration) = %q, want %q", typ, "BSD-3-Clause")
+	}
+
+	declared := "SPDX-License-Identifier: BSD-3-Clause OR BSD-2-Clause\n\n" + canonicalBSD3
+	typ, _, _ = classify(declared, DefaultMinConfidence, nil)
+	if typ != "BSD-3-Clause OR BSD-2-Clause" {
+		t.Fatalf("classify(declared dual-license) = %q, want %q", typ, "BSD-3-Clause OR BSD-2-Clause")
+	}
+}
+
+// bsd3WithoutCopyrightHolderPhrase is a real-world BSD-3-Clause license
+// (jessevdk/go-flags) that swaps "the copyright holder" for the author's
+// name, so it scores slightly below a verbatim BSD-2-Clause match even
+// though it's the more specific, 3-clause license.
+const bsd3WithoutCopyrightHolderPhrase = `Copyright (c) 2012 Jesse van den Kieboom. All rights reserved.
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+
+   * Redistributions of source code must retain the above copyright
+     notice, this list of conditions and the following disclaimer.
+   * Redistributions in binary form must reproduce the above
+     copyright notice, this list of conditions and the following disclaimer
+     in the documentation and/or other materials provided with the
+     distribution.
+   * Neither the name of Jesse van den Kieboom nor the names of its
+     contributors may be used to endorse or promote products derived from
+     this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+"AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES ARE DISCLAIMED.
+`
+
+func TestClassifyPrefersMoreSpecificLicenseOverMarginallyHigherScore(t *testing.T) {
+	typ, candidates, _ := classify(bsd3WithoutCopyrightHolderPhrase, DefaultMinConfidence, nil)
+	if typ != "BSD-3-Clause" {
+		t.Fatalf("classify(bsd3WithoutCopyrightHolderPhrase) = %q, candidates=%v, want %q", typ, candidates, "BSD-3-Clause")
+	}
+}
+
+func TestClassifyTrustsADeclaredIdentifierEvenWithoutAMatchingTemplate(t *testing.T) {
+	// A custom, heavily reworded license body that no built-in template
+	// scores above the confidence threshold against, but which declares its
+	// SPDX identifier explicitly; that declaration is the strongest signal
+	// we have and must not be discarded just because the similarity scan
+	// came up empty.
+	text := "SPDX-License-Identifier: MIT\n\n" +
+		"This is a bespoke license grant written entirely in the project's " +
+		"own words, sharing none of the wording any built-in template scores " +
+		"against, yet the identifier above unambiguously names the license."
+
+	typ, candidates, attribution := classify(text, DefaultMinConfidence, nil)
+	if typ != "MIT" {
+		t.Fatalf("classify(declared-only MIT) = %q, candidates=%v, want %q", typ, candidates, "MIT")
+	}
+	if candidates != nil {
+		t.Fatalf("classify(declared-only MIT) candidates = %v, want nil (no template cleared the threshold)", candidates)
+	}
+	if attribution != "" {
+		t.Fatalf("classify(declared-only MIT) attribution = %q, want empty", attribution)
+	}
+}
+
+func TestClassifyScoresEveryCustomTemplateForAnSPDXID(t *testing.T) {
+	// A CustomLicense entry may list several templates: unrelated boilerplate
+	// plus the one that actually matches. Every one of them must be scored,
+	// not just the last one read.
+	extra := map[string][]string{
+		"Acme-1.0": {
+			"This text is completely unrelated boilerplate that never matches anything.",
+			canonicalMIT,
+		},
+	}
+
+	typ, candidates, attribution := classify(canonicalMIT, DefaultMinConfidence, extra)
+	if typ != "Acme-1.0" {
+		t.Fatalf("classify() = %q, candidates=%v, want %q (the second registered template)", typ, candidates, "Acme-1.0")
+	}
+	if attribution != "custom:Acme-1.0" {
+		t.Fatalf("attribution = %q, want %q", attribution, "custom:Acme-1.0")
+	}
+}
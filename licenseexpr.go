@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExprOp identifies the kind of node in a parsed SPDX license expression.
+type ExprOp int
+
+const (
+	OpLeaf ExprOp = iota
+	OpAnd
+	OpOr
+)
+
+// Expr is a node in a parsed SPDX license expression, such as
+// "MIT OR Apache-2.0" or "(MIT AND BSD-3-Clause)".
+type Expr struct {
+	Op       ExprOp
+	ID       string
+	Children []*Expr
+}
+
+// ParseExpr parses the subset of the SPDX license expression grammar wwhrd
+// needs: identifiers, AND, OR and parentheses.
+func ParseExpr(s string) (*Expr, error) {
+	p := &exprParser{tokens: tokenizeExpr(s)}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q in license expression %q", p.tokens[p.pos], s)
+	}
+	return e, nil
+}
+
+func tokenizeExpr(s string) []string {
+	s = strings.ReplaceAll(s, "(", " ( ")
+	s = strings.ReplaceAll(s, ")", " ) ")
+	return strings.Fields(s)
+}
+
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *exprParser) parseOr() (*Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for strings.EqualFold(p.peek(), "OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Op: OpOr, Children: []*Expr{left, right}}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (*Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for strings.EqualFold(p.peek(), "AND") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Op: OpAnd, Children: []*Expr{left, right}}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (*Expr, error) {
+	tok := p.next()
+
+	switch tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of license expression")
+	case "(":
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis in license expression")
+		}
+		return e, nil
+	default:
+		return &Expr{Op: OpLeaf, ID: tok}, nil
+	}
+}
+
+// Approved evaluates the expression against a whitelist/blacklist of SPDX
+// identifiers. An OR expression is approved if at least one operand is
+// (recursively) approved. An AND expression requires every operand to be
+// individually approved.
+func (e *Expr) Approved(whitelist, blacklist map[string]bool) bool {
+	switch e.Op {
+	case OpLeaf:
+		return whitelist[e.ID] && !blacklist[e.ID]
+	case OpAnd:
+		for _, c := range e.Children {
+			if !c.Approved(whitelist, blacklist) {
+				return false
+			}
+		}
+		return true
+	case OpOr:
+		for _, c := range e.Children {
+			if c.Approved(whitelist, blacklist) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestParseExprAndApproved(t *testing.T) {
+	cases := []struct {
+		name       string
+		expr       string
+		whitelist  []string
+		blacklist  []string
+		wantOK     bool
+		wantParsed bool
+	}{
+		{"single whitelisted", "MIT", []string{"MIT"}, nil, true, true},
+		{"single not whitelisted", "GPL-3.0", []string{"MIT"}, nil, false, true},
+		{"or approves if any operand whitelisted", "GPL-3.0 OR MIT", []string{"MIT"}, nil, true, true},
+		{"or approves via the clean operand even if the other is blacklisted", "GPL-3.0 OR MIT", []string{"MIT"}, []string{"GPL-3.0"}, true, true},
+		{"and requires every operand approved", "MIT AND Apache-2.0", []string{"MIT", "Apache-2.0"}, nil, true, true},
+		{"and fails if one operand not whitelisted", "MIT AND GPL-3.0", []string{"MIT", "Apache-2.0"}, nil, false, true},
+		{"parens group correctly", "(MIT OR GPL-3.0) AND Apache-2.0", []string{"MIT", "Apache-2.0"}, nil, true, true},
+		{"unbalanced parens fails to parse", "(MIT OR GPL-3.0", nil, nil, false, false},
+
+		// Nested expressions: the OR branch must recurse into each child's
+		// Approved rather than flattening every descendant leaf, or it
+		// mistakes "some leaf somewhere is whitelisted and none blacklisted"
+		// for "some whole operand is satisfiable".
+		{"nested and-within-or fails when neither branch is satisfiable", "(MIT AND GPL-3.0) OR BSD-3-Clause", []string{"MIT"}, nil, false, true},
+		{"nested and-within-or succeeds via the clean bare operand", "(MIT AND GPL-3.0) OR BSD-3-Clause", []string{"MIT", "BSD-3-Clause"}, nil, true, true},
+		{"nested and-within-or ignores a blacklist hit confined to the other branch", "(MIT AND GPL-3.0) OR BSD-3-Clause", []string{"BSD-3-Clause"}, []string{"GPL-3.0"}, true, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := ParseExpr(c.expr)
+			if c.wantParsed && err != nil {
+				t.Fatalf("ParseExpr(%q) returned unexpected error: %v", c.expr, err)
+			}
+			if !c.wantParsed {
+				if err == nil {
+					t.Fatalf("ParseExpr(%q) = %v, want an error", c.expr, expr)
+				}
+				return
+			}
+
+			got := expr.Approved(toSet(c.whitelist), toSet(c.blacklist))
+			if got != c.wantOK {
+				t.Fatalf("Approved() = %v, want %v", got, c.wantOK)
+			}
+		})
+	}
+}
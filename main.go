@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	parser := newCli()
+
+	if _, err := parser.Parse(); err != nil {
+		if flagsErr, ok := err.(*flags.Error); ok {
+			if flagsErr.Type == flags.ErrHelp || flagsErr.Type == VersionHelp {
+				fmt.Println(flagsErr.Message)
+				os.Exit(0)
+			}
+		}
+		log.Error(err)
+		os.Exit(1)
+	}
+}
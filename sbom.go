@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sbomEntry is the normalized, format-agnostic view of a single package used
+// to render any of the supported SBOM formats.
+type sbomEntry struct {
+	Name             string
+	Version          string
+	DownloadLocation string
+	License          License
+}
+
+// spdxLicenseID returns the SPDX license identifier to use for a concluded
+// license, falling back to NOASSERTION when the classifier didn't recognize
+// the license text.
+func spdxLicenseID(lic License) string {
+	if !lic.Recognized() {
+		return "NOASSERTION"
+	}
+	return lic.Type
+}
+
+// buildSBOMEntries turns the package/license map produced by the check loop
+// into a stable, sorted list of SBOM entries.
+func buildSBOMEntries(lics map[string]License) []sbomEntry {
+	entries := make([]sbomEntry, 0, len(lics))
+	for pkg, lic := range lics {
+		version := lic.Version
+		if version == "" {
+			version = "NOASSERTION"
+		}
+
+		entries = append(entries, sbomEntry{
+			Name:             pkg,
+			Version:          version,
+			DownloadLocation: fmt.Sprintf("https://%s", pkg),
+			License:          lic,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// documentNamespace builds the unique URI SPDX requires to identify a
+// document, derived from the scanned module's own path plus the time the
+// document was created so re-running the scan doesn't collide with a
+// previous report.
+func documentNamespace(modulePath string, created time.Time) string {
+	if modulePath == "" {
+		modulePath = "unknown"
+	}
+	return fmt.Sprintf("https://spdx.org/spdxdocs/%s-%d", modulePath, created.Unix())
+}
+
+// WriteSBOM renders lics in the requested format and writes the result to
+// path, or to stdout when path is empty or "-". modulePath and created
+// identify the scanned project and the time of the scan, both required to
+// populate the SPDX document's mandatory creation info.
+func WriteSBOM(format, path string, lics map[string]License, modulePath string, created time.Time) error {
+	entries := buildSBOMEntries(lics)
+	namespace := documentNamespace(modulePath, created)
+	creator := fmt.Sprintf("Tool: wwhrd-%s", version)
+	createdStr := created.Format(time.RFC3339)
+
+	var (
+		b   []byte
+		err error
+	)
+
+	switch format {
+	case "spdx-tv":
+		b = []byte(renderSPDXTagValue(entries, namespace, creator, createdStr))
+	case "spdx-json":
+		b, err = json.MarshalIndent(renderSPDXJSON(entries, namespace, creator, createdStr), "", "  ")
+	case "cyclonedx-json":
+		b, err = json.MarshalIndent(renderCycloneDX(entries), "", "  ")
+	default:
+		return fmt.Errorf("unknown sbom format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if path == "" || path == "-" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+
+	return os.WriteFile(path, b, 0644)
+}
+
+func renderSPDXTagValue(entries []sbomEntry, namespace, creator, created string) string {
+	var sb strings.Builder
+
+	sb.WriteString("SPDXVersion: SPDX-2.3\n")
+	sb.WriteString("DataLicense: CC0-1.0\n")
+	sb.WriteString("SPDXID: SPDXRef-DOCUMENT\n")
+	sb.WriteString("DocumentName: wwhrd-sbom\n")
+	sb.WriteString(fmt.Sprintf("DocumentNamespace: %s\n", namespace))
+	sb.WriteString(fmt.Sprintf("Creator: %s\n", creator))
+	sb.WriteString(fmt.Sprintf("Created: %s\n\n", created))
+
+	for i, e := range entries {
+		ref := fmt.Sprintf("SPDXRef-Package-%d", i)
+		sb.WriteString(fmt.Sprintf("PackageName: %s\n", e.Name))
+		sb.WriteString(fmt.Sprintf("SPDXID: %s\n", ref))
+		sb.WriteString(fmt.Sprintf("PackageVersion: %s\n", e.Version))
+		sb.WriteString(fmt.Sprintf("PackageDownloadLocation: %s\n", e.DownloadLocation))
+		sb.WriteString("PackageFilesAnalyzed: false\n")
+		sb.WriteString(fmt.Sprintf("PackageLicenseConcluded: %s\n", spdxLicenseID(e.License)))
+		sb.WriteString(fmt.Sprintf("PackageLicenseDeclared: %s\n", spdxLicenseID(e.License)))
+		if !e.License.Recognized() && e.License.Text != "" {
+			licRef := fmt.Sprintf("LicenseRef-%d", i)
+			sb.WriteString(fmt.Sprintf("PackageLicenseComments: see %s\n", licRef))
+			sb.WriteString("\n")
+			sb.WriteString(fmt.Sprintf("LicenseID: %s\n", licRef))
+			sb.WriteString(fmt.Sprintf("ExtractedText: <text>%s</text>\n", e.License.Text))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// spdxDocument, spdxPackage and spdxExtractedLicensingInfo mirror the subset
+// of the SPDX 2.3 JSON schema wwhrd emits.
+type spdxDocument struct {
+	SPDXVersion           string                       `json:"spdxVersion"`
+	DataLicense           string                       `json:"dataLicense"`
+	SPDXID                string                       `json:"SPDXID"`
+	Name                  string                       `json:"name"`
+	DocumentNamespace     string                       `json:"documentNamespace"`
+	CreationInfo          spdxCreationInfo             `json:"creationInfo"`
+	Packages              []spdxPackage                `json:"packages"`
+	HasExtractedLicensing []spdxExtractedLicensingInfo `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+// spdxCreationInfo records who generated the document and when, both
+// mandatory per the SPDX 2.3 schema.
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string `json:"name"`
+	SPDXID           string `json:"SPDXID"`
+	VersionInfo      string `json:"versionInfo"`
+	DownloadLocation string `json:"downloadLocation"`
+	FilesAnalyzed    bool   `json:"filesAnalyzed"`
+	LicenseConcluded string `json:"licenseConcluded"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+}
+
+type spdxExtractedLicensingInfo struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name"`
+}
+
+func renderSPDXJSON(entries []sbomEntry, namespace, creator, created string) spdxDocument {
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "wwhrd-sbom",
+		DocumentNamespace: namespace,
+		CreationInfo:      spdxCreationInfo{Created: created, Creators: []string{creator}},
+	}
+
+	for i, e := range entries {
+		lic := spdxLicenseID(e.License)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:             e.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d", i),
+			VersionInfo:      e.Version,
+			DownloadLocation: e.DownloadLocation,
+			FilesAnalyzed:    false,
+			LicenseConcluded: lic,
+			LicenseDeclared:  lic,
+		})
+
+		if !e.License.Recognized() && e.License.Text != "" {
+			doc.HasExtractedLicensing = append(doc.HasExtractedLicensing, spdxExtractedLicensingInfo{
+				LicenseID:     fmt.Sprintf("LicenseRef-%d", i),
+				ExtractedText: e.License.Text,
+				Name:          e.Name,
+			})
+		}
+	}
+
+	return doc
+}
+
+// cyclonedxDocument and cyclonedxComponent mirror the subset of the
+// CycloneDX 1.5 JSON schema wwhrd emits.
+type cyclonedxDocument struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Components  []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxComponent struct {
+	Type     string             `json:"type"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version"`
+	Purl     string             `json:"purl"`
+	Licenses []cyclonedxLicense `json:"licenses"`
+}
+
+type cyclonedxLicense struct {
+	License cyclonedxLicenseChoice `json:"license"`
+}
+
+type cyclonedxLicenseChoice struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+func renderCycloneDX(entries []sbomEntry) cyclonedxDocument {
+	doc := cyclonedxDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+	}
+
+	for _, e := range entries {
+		choice := cyclonedxLicenseChoice{}
+		if e.License.Recognized() {
+			choice.ID = e.License.Type
+		} else {
+			choice.Name = "Unrecognized"
+			choice.Text = e.License.Text
+		}
+
+		doc.Components = append(doc.Components, cyclonedxComponent{
+			Type:     "library",
+			Name:     e.Name,
+			Version:  e.Version,
+			Purl:     fmt.Sprintf("pkg:golang/%s", e.Name),
+			Licenses: []cyclonedxLicense{{License: choice}},
+		})
+	}
+
+	return doc
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDocumentNamespaceIsDerivedFromTheModulePathAndIsStable(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first := documentNamespace("github.com/SpiderOak/wwhrd", created)
+	second := documentNamespace("github.com/SpiderOak/wwhrd", created)
+	if first != second {
+		t.Fatalf("documentNamespace is not stable for the same inputs: %q != %q", first, second)
+	}
+
+	other := documentNamespace("github.com/other/module", created)
+	if first == other {
+		t.Fatalf("documentNamespace(%q) and documentNamespace(%q) collided: %q", "github.com/SpiderOak/wwhrd", "github.com/other/module", first)
+	}
+}
+
+func TestBuildSBOMEntriesFallsBackToNOASSERTION(t *testing.T) {
+	lics := map[string]License{
+		"example.com/foo": {Type: "MIT"},
+		"example.com/bar": {Type: Unknown},
+	}
+
+	entries := buildSBOMEntries(lics)
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+
+	// Sorted by name: bar before foo.
+	if entries[0].Name != "example.com/bar" || entries[0].Version != "NOASSERTION" {
+		t.Fatalf("entries[0] = %+v, want name example.com/bar with version NOASSERTION", entries[0])
+	}
+	if entries[1].Name != "example.com/foo" {
+		t.Fatalf("entries[1].Name = %q, want example.com/foo", entries[1].Name)
+	}
+}
+
+func TestRenderSPDXJSONUsesNOASSERTIONForUnrecognizedLicenses(t *testing.T) {
+	entries := buildSBOMEntries(map[string]License{
+		"example.com/foo": {Type: "MIT", Version: "v1.0.0"},
+		"example.com/bar": {Type: Unknown, Text: "some unrecognized text"},
+	})
+
+	doc := renderSPDXJSON(entries, "https://spdx.org/spdxdocs/example-1", "Tool: wwhrd-dev", "2024-01-01T00:00:00Z")
+	if len(doc.Packages) != 2 {
+		t.Fatalf("len(doc.Packages) = %d, want 2", len(doc.Packages))
+	}
+	if doc.DocumentNamespace == "" || doc.CreationInfo.Created == "" || len(doc.CreationInfo.Creators) != 1 {
+		t.Fatalf("doc creation info = %+v, want a namespace and creation info populated", doc)
+	}
+	for _, p := range doc.Packages {
+		if p.FilesAnalyzed {
+			t.Errorf("package %s FilesAnalyzed = true, want false (no packageVerificationCode is computed)", p.Name)
+		}
+	}
+
+	byName := make(map[string]spdxPackage)
+	for _, p := range doc.Packages {
+		byName[p.Name] = p
+	}
+
+	if got := byName["example.com/foo"].LicenseConcluded; got != "MIT" {
+		t.Errorf("foo LicenseConcluded = %q, want MIT", got)
+	}
+	if got := byName["example.com/bar"].LicenseConcluded; got != "NOASSERTION" {
+		t.Errorf("bar LicenseConcluded = %q, want NOASSERTION", got)
+	}
+
+	if len(doc.HasExtractedLicensing) != 1 || doc.HasExtractedLicensing[0].Name != "example.com/bar" {
+		t.Errorf("HasExtractedLicensing = %+v, want a single entry for example.com/bar", doc.HasExtractedLicensing)
+	}
+}
+
+func TestRenderCycloneDXDistinguishesRecognizedLicenses(t *testing.T) {
+	entries := buildSBOMEntries(map[string]License{
+		"example.com/foo": {Type: "MIT", Version: "v1.0.0"},
+		"example.com/bar": {Type: Unknown, Text: "some unrecognized text"},
+	})
+
+	doc := renderCycloneDX(entries)
+
+	byName := make(map[string]cyclonedxComponent)
+	for _, c := range doc.Components {
+		byName[c.Name] = c
+	}
+
+	foo := byName["example.com/foo"].Licenses[0].License
+	if foo.ID != "MIT" || foo.Text != "" {
+		t.Errorf("foo license = %+v, want ID=MIT with no extracted text", foo)
+	}
+
+	bar := byName["example.com/bar"].Licenses[0].License
+	if bar.ID != "" || bar.Text != "some unrecognized text" {
+		t.Errorf("bar license = %+v, want no ID with the extracted text", bar)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Import is a single non-stdlib import path together with the resolved
+// module version and on-disk directory providing it.
+type Import struct {
+	Path    string
+	Version string
+	Dir     string
+}
+
+// WalkImports returns the sorted, de-duplicated set of non-stdlib imports
+// reachable from the module rooted at root (each annotated with its
+// resolved module version), along with the import path of that root
+// module itself.
+func WalkImports(root string) (imports []Import, rootModule string, err error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedModule,
+		Dir:  root,
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, "", err
+	}
+	if len(pkgs) > 0 && pkgs[0].Module != nil {
+		rootModule = pkgs[0].Module.Path
+	}
+
+	seen := make(map[string]bool)
+
+	var walk func(pkg *packages.Package)
+	walk = func(pkg *packages.Package) {
+		for path, imp := range pkg.Imports {
+			if isStdlib(path) || seen[path] {
+				continue
+			}
+			seen[path] = true
+			imports = append(imports, Import{Path: path, Version: moduleVersion(imp), Dir: moduleDir(imp)})
+			walk(imp)
+		}
+	}
+
+	for _, pkg := range pkgs {
+		walk(pkg)
+	}
+
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Path < imports[j].Path })
+	return imports, rootModule, nil
+}
+
+// moduleVersion returns the resolved module version providing pkg, or "" if
+// pkg isn't part of a module (e.g. a GOPATH-mode dependency).
+func moduleVersion(pkg *packages.Package) string {
+	if pkg.Module == nil {
+		return ""
+	}
+	return pkg.Module.Version
+}
+
+// moduleDir returns the on-disk root directory of the module providing pkg
+// (where its LICENSE file lives), or "" if pkg isn't part of a module.
+func moduleDir(pkg *packages.Package) string {
+	if pkg.Module == nil {
+		return ""
+	}
+	return pkg.Module.Dir
+}
+
+// isStdlib reports whether an import path belongs to the standard library,
+// identified by the absence of a dot in its first path component.
+func isStdlib(path string) bool {
+	first := strings.SplitN(path, "/", 2)[0]
+	return !strings.Contains(first, ".")
+}